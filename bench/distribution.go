@@ -0,0 +1,219 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Distribution generates a stream of IDs over [0, N). Benchmarks select
+// bitmap and profile generators independently from the registry below, so
+// e.g. exponential row IDs can be mixed against uniform column IDs.
+type Distribution interface {
+	Next() uint64
+}
+
+// DistributionConfig names a registered Distribution and its parameters.
+// Param names and meanings are specific to each distribution; see the
+// new*Distribution constructors below.
+type DistributionConfig struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params"`
+}
+
+// distributionConstructor builds a Distribution over [0, n) from a seed and
+// a set of named parameters.
+type distributionConstructor func(seed int64, n uint64, params map[string]float64) (Distribution, error)
+
+// distributionRegistry holds the constructor for each known distribution,
+// keyed by the name used in DistributionConfig.Name.
+var distributionRegistry = map[string]distributionConstructor{
+	"uniform":           newUniformDistribution,
+	"zipf":              newZipfDistribution,
+	"zipf-mandelbrot":   newZipfMandelbrotDistribution,
+	"zipf-incrementing": newZipfIncrementingDistribution,
+	"hotspot":           newHotspotDistribution,
+	"exponential":       newExponentialDistribution,
+}
+
+// NewFromConfig builds the Distribution named by cfg.Name over [0, n),
+// seeded with seed.
+func NewFromConfig(seed int64, n uint64, cfg DistributionConfig) (Distribution, error) {
+	ctor, ok := distributionRegistry[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown distribution: %q", cfg.Name)
+	}
+	return ctor(seed, n, cfg.Params)
+}
+
+// param looks up key in params, falling back to def if it is absent.
+func param(params map[string]float64, key string, def float64) float64 {
+	if v, ok := params[key]; ok {
+		return v
+	}
+	return def
+}
+
+// uniformDistribution draws IDs uniformly at random from [0, n).
+type uniformDistribution struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+	n   uint64
+}
+
+func newUniformDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	return &uniformDistribution{rnd: rand.New(rand.NewSource(seed)), n: n}, nil
+}
+
+func (d *uniformDistribution) Next() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return uint64(d.rnd.Int63n(int64(d.n)))
+}
+
+// zipfDistribution is a bare rand.Zipf draw with no scrambling, for callers
+// that need the raw, clustered-near-0 distribution themselves (e.g.
+// ZipfLatest, which derives recency from the unscrambled value). Params:
+// "exponent" and "ratio" (see getZipfOffset).
+type zipfDistribution struct {
+	mu  sync.Mutex
+	rng *rand.Zipf
+}
+
+func newZipfDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	exponent := param(params, "exponent", 1.001)
+	ratio := param(params, "ratio", 0.5)
+	rnd := rand.New(rand.NewSource(seed))
+	offset := getZipfOffset(int64(n), exponent, ratio)
+	return &zipfDistribution{rng: rand.NewZipf(rnd, exponent, offset, n-1)}, nil
+}
+
+func (d *zipfDistribution) Next() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rng.Uint64()
+}
+
+// zipfMandelbrotDistribution is the Zipf-Mandelbrot generator previously
+// built into bench.Zipf: rand.Zipf scrambled with PermutationGenerator.
+// Params: "exponent" and "ratio" (see getZipfOffset).
+type zipfMandelbrotDistribution struct {
+	mu   sync.Mutex
+	rng  *rand.Zipf
+	perm *PermutationGenerator
+}
+
+func newZipfMandelbrotDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	exponent := param(params, "exponent", 1.001)
+	ratio := param(params, "ratio", 0.5)
+	rnd := rand.New(rand.NewSource(seed))
+	offset := getZipfOffset(int64(n), exponent, ratio)
+	rng := rand.NewZipf(rnd, exponent, offset, n-1)
+	perm := NewPermutationGenerator(int64(n), seed)
+	return &zipfMandelbrotDistribution{rng: rng, perm: perm}, nil
+}
+
+func (d *zipfMandelbrotDistribution) Next() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return uint64(d.perm.Next(int64(d.rng.Uint64())))
+}
+
+// zipfIncrementingDistribution wraps incrementingZipfGenerator, growing the
+// key range every "increment-every" draws by "increment-by" items. Params:
+// "theta", "scramble-mode" (0 = none, 1 = FNV-1a, 2 = PermutationGenerator),
+// "increment-every", "increment-by".
+type zipfIncrementingDistribution struct {
+	mu             sync.Mutex
+	gen            *incrementingZipfGenerator
+	calls          uint64
+	incrementEvery uint64
+	incrementBy    uint64
+}
+
+func newZipfIncrementingDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	theta := param(params, "theta", 0.99)
+	scrambleMode := ""
+	switch int(param(params, "scramble-mode", 0)) {
+	case 1:
+		scrambleMode = scrambleModeFNV
+	case 2:
+		scrambleMode = scrambleModePermutation
+	}
+	return &zipfIncrementingDistribution{
+		gen:            newIncrementingZipfGenerator(seed, n, theta, scrambleMode),
+		incrementEvery: uint64(param(params, "increment-every", 0)),
+		incrementBy:    uint64(param(params, "increment-by", 0)),
+	}, nil
+}
+
+func (d *zipfIncrementingDistribution) Next() uint64 {
+	d.mu.Lock()
+	d.calls++
+	if d.incrementEvery > 0 && d.calls%d.incrementEvery == 0 {
+		d.gen.IncrementMax(d.incrementBy)
+	}
+	d.mu.Unlock()
+	return d.gen.Next()
+}
+
+// hotspotDistribution sends a fraction of accesses ("hot-opn-fraction") to
+// a fraction of the keyspace ("hot-data-fraction"), modeling a small set of
+// frequently-touched keys.
+type hotspotDistribution struct {
+	mu              sync.Mutex
+	rnd             *rand.Rand
+	n               uint64
+	hotDataFraction float64
+	hotOpnFraction  float64
+}
+
+func newHotspotDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	return &hotspotDistribution{
+		rnd:             rand.New(rand.NewSource(seed)),
+		n:               n,
+		hotDataFraction: param(params, "hot-data-fraction", 0.1),
+		hotOpnFraction:  param(params, "hot-opn-fraction", 0.9),
+	}, nil
+}
+
+func (d *hotspotDistribution) Next() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hotN := uint64(float64(d.n) * d.hotDataFraction)
+	if hotN == 0 {
+		hotN = 1
+	}
+	if d.rnd.Float64() < d.hotOpnFraction {
+		return uint64(d.rnd.Int63n(int64(hotN)))
+	}
+	coldN := d.n - hotN
+	if coldN == 0 {
+		return 0
+	}
+	return hotN + uint64(d.rnd.Int63n(int64(coldN)))
+}
+
+// exponentialDistribution draws from an exponential distribution with rate
+// "lambda", scaled and wrapped into [0, n).
+type exponentialDistribution struct {
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	n      uint64
+	lambda float64
+}
+
+func newExponentialDistribution(seed int64, n uint64, params map[string]float64) (Distribution, error) {
+	return &exponentialDistribution{
+		rnd:    rand.New(rand.NewSource(seed)),
+		n:      n,
+		lambda: param(params, "lambda", 1.0),
+	}, nil
+}
+
+func (d *exponentialDistribution) Next() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := uint64((d.rnd.ExpFloat64() / d.lambda) * float64(d.n))
+	return id % d.n
+}
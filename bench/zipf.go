@@ -8,29 +8,43 @@ import (
 	"time"
 )
 
-// Zipf sets random bits according to the Zipf-Mandelbrot distribution.
-// This distribution accepts two parameters, Exponent and Ratio, for both bitmaps and profiles.
-// It also uses PermutationGenerator to permute IDs randomly.
+// readOperations are the query operations valid for ReadOperation; Operation
+// itself accepts only the write operations "set" and "clear".
+var readOperations = map[string]bool{
+	"count":  true,
+	"row":    true,
+	"topn":   true,
+	"bitmap": true,
+}
+
+// Zipf draws bitmap and profile IDs from independently configurable
+// Distributions (see distribution.go) and issues queries against them,
+// defaulting both to Zipf-Mandelbrot.
+//
+// Operation selects the write issued on each iteration ("set" or "clear").
+// Setting ReadWriteRatio to a fraction in (0, 1] turns a portion of
+// iterations into ReadOperation reads ("count", "row", "topn", or
+// "bitmap") drawn from the same ID stream, with latencies recorded
+// separately per operation in the results.
 type Zipf struct {
 	HasClient
-	Name            string  `json:"name"`
-	BaseBitmapID    int64   `json:"base-bitmap-id"`
-	BaseProfileID   int64   `json:"base-profile-id"`
-	BitmapIDRange   int64   `json:"bitmap-id-range"`
-	ProfileIDRange  int64   `json:"profile-id-range"`
-	Iterations      int     `json:"iterations"`
-	Seed            int64   `json:"seed"`
-	Index           string  `json:"index"`
-	Frame           string  `json:"frame"`
-	BitmapExponent  float64 `json:"bitmap-exponent"`
-	BitmapRatio     float64 `json:"bitmap-ratio"`
-	ProfileExponent float64 `json:"profile-exponent"`
-	ProfileRatio    float64 `json:"profile-ratio"`
-	Operation       string  `json:"operation"`
-	bitmapRng       *rand.Zipf
-	profileRng      *rand.Zipf
-	bitmapPerm      *PermutationGenerator
-	profilePerm     *PermutationGenerator
+	Name                string             `json:"name"`
+	BaseBitmapID        int64              `json:"base-bitmap-id"`
+	BaseProfileID       int64              `json:"base-profile-id"`
+	BitmapIDRange       int64              `json:"bitmap-id-range"`
+	ProfileIDRange      int64              `json:"profile-id-range"`
+	Iterations          int                `json:"iterations"`
+	Seed                int64              `json:"seed"`
+	Index               string             `json:"index"`
+	Frame               string             `json:"frame"`
+	BitmapDistribution  DistributionConfig `json:"bitmap-distribution"`
+	ProfileDistribution DistributionConfig `json:"profile-distribution"`
+	Operation           string             `json:"operation"`
+	ReadOperation       string             `json:"read-operation"`
+	ReadWriteRatio      float64            `json:"read-write-ratio"`
+	bitmapDist          Distribution
+	profileDist         Distribution
+	mixRng              *rand.Rand
 }
 
 // Offset is the true parameter used by the Zipf distribution, but the ratio,
@@ -50,19 +64,38 @@ func getZipfOffset(N int64, exp, ratio float64) float64 {
 func (b *Zipf) Init(hosts []string, agentNum int) error {
 	b.Name = "zipf"
 	b.Seed = b.Seed + int64(agentNum)
-	rnd := rand.New(rand.NewSource(b.Seed))
-	bitmapOffset := getZipfOffset(b.BitmapIDRange, b.BitmapExponent, b.BitmapRatio)
-	b.bitmapRng = rand.NewZipf(rnd, b.BitmapExponent, bitmapOffset, uint64(b.BitmapIDRange-1))
-	profileOffset := getZipfOffset(b.ProfileIDRange, b.ProfileExponent, b.ProfileRatio)
-	b.profileRng = rand.NewZipf(rnd, b.ProfileExponent, profileOffset, uint64(b.ProfileIDRange-1))
 
-	b.bitmapPerm = NewPermutationGenerator(b.BitmapIDRange, b.Seed)
-	b.profilePerm = NewPermutationGenerator(b.ProfileIDRange, b.Seed+1)
+	if b.BitmapDistribution.Name == "" {
+		b.BitmapDistribution.Name = "zipf-mandelbrot"
+	}
+	if b.ProfileDistribution.Name == "" {
+		b.ProfileDistribution.Name = "zipf-mandelbrot"
+	}
+
+	var err error
+	b.bitmapDist, err = NewFromConfig(b.Seed, uint64(b.BitmapIDRange), b.BitmapDistribution)
+	if err != nil {
+		return fmt.Errorf("building bitmap distribution: %v", err)
+	}
+	b.profileDist, err = NewFromConfig(b.Seed+1, uint64(b.ProfileIDRange), b.ProfileDistribution)
+	if err != nil {
+		return fmt.Errorf("building profile distribution: %v", err)
+	}
 
 	if b.Operation != "set" && b.Operation != "clear" {
 		return fmt.Errorf("Unsupported operation: \"%s\" (must be \"set\" or \"clear\")", b.Operation)
 	}
-	err := initIndex(hosts[0], b.Index, b.Frame)
+	if b.ReadWriteRatio > 0 {
+		if !readOperations[b.ReadOperation] {
+			return fmt.Errorf("Unsupported read-operation: \"%s\" (must be one of \"count\", \"row\", \"topn\", \"bitmap\")", b.ReadOperation)
+		}
+		if b.ReadWriteRatio > 1 {
+			return fmt.Errorf("read-write-ratio must be in [0, 1], got %v", b.ReadWriteRatio)
+		}
+		b.mixRng = rand.New(rand.NewSource(b.Seed + 2))
+	}
+
+	err = initIndex(hosts[0], b.Index, b.Frame)
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -70,6 +103,28 @@ func (b *Zipf) Init(hosts []string, agentNum int) error {
 	return b.HasClient.Init(hosts, agentNum)
 }
 
+// queryForOperation builds the PQL query for op against the given rowID
+// and columnID.
+func queryForOperation(op, frame string, rowID, columnID int64) string {
+	switch op {
+	case "set":
+		return fmt.Sprintf("SetBit(frame='%s', rowID=%d, columnID=%d)", frame, rowID, columnID)
+	case "clear":
+		return fmt.Sprintf("ClearBit(frame='%s', rowID=%d, columnID=%d)", frame, rowID, columnID)
+	case "count":
+		return fmt.Sprintf("Count(Bitmap(frame='%s', rowID=%d))", frame, rowID)
+	case "row":
+		// "Row" is the current PQL name for what used to be "Bitmap"; both
+		// are exposed so either API version can be benchmarked.
+		return fmt.Sprintf("Row(frame='%s', rowID=%d)", frame, rowID)
+	case "bitmap":
+		return fmt.Sprintf("Bitmap(frame='%s', rowID=%d)", frame, rowID)
+	case "topn":
+		return fmt.Sprintf("TopN(frame='%s')", frame)
+	}
+	panic(fmt.Sprintf("unknown operation: %q", op))
+}
+
 // Run runs the Zipf benchmark
 func (b *Zipf) Run(ctx context.Context) map[string]interface{} {
 	results := make(map[string]interface{})
@@ -77,29 +132,40 @@ func (b *Zipf) Run(ctx context.Context) map[string]interface{} {
 		results["error"] = fmt.Errorf("No client set for Zipf")
 		return results
 	}
-	operation := "SetBit"
-	if b.Operation == "clear" {
-		operation = "ClearBit"
+	stats := map[string]*Stats{b.Operation: NewStats()}
+	if b.ReadWriteRatio > 0 {
+		stats[b.ReadOperation] = NewStats()
 	}
-	s := NewStats()
+
 	var start time.Time
 	for n := 0; n < b.Iterations; n++ {
-		// generate IDs from Zipf distribution
-		bitmapIDOriginal := b.bitmapRng.Uint64()
-		profIDOriginal := b.profileRng.Uint64()
-		// permute IDs randomly, but repeatably
-		bitmapID := b.bitmapPerm.Next(int64(bitmapIDOriginal))
-		profID := b.profilePerm.Next(int64(profIDOriginal))
-
-		query := fmt.Sprintf("%s(frame='%s', rowID=%d, columnID=%d)", operation, b.Frame, b.BaseBitmapID+int64(bitmapID), b.BaseProfileID+int64(profID))
+		bitmapID := b.bitmapDist.Next()
+		profID := b.profileDist.Next()
+
+		op := b.Operation
+		if b.ReadWriteRatio > 0 && b.mixRng.Float64() < b.ReadWriteRatio {
+			op = b.ReadOperation
+		}
+
+		query := queryForOperation(op, b.Frame, b.BaseBitmapID+int64(bitmapID), b.BaseProfileID+int64(profID))
 		start = time.Now()
 		_, err := b.client.ExecuteQuery(ctx, b.Index, query, true)
 		if err != nil {
 			results["error"] = err.Error()
 			return results
 		}
-		s.Add(time.Now().Sub(start))
+		stats[op].Add(time.Now().Sub(start))
+	}
+
+	if len(stats) == 1 {
+		// single-operation runs keep the original flat result shape
+		AddToResults(stats[b.Operation], results)
+	} else {
+		for op, s := range stats {
+			opResults := make(map[string]interface{})
+			AddToResults(s, opResults)
+			results[op] = opResults
+		}
 	}
-	AddToResults(s, results)
 	return results
 }
@@ -0,0 +1,126 @@
+package bench
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// incrementingZipfGenerator is the Zipfian generator from Gray et al.,
+// "Quickly Generating Billion-Record Synthetic Databases" (SIGMOD 1994).
+// Its key range can grow at runtime via IncrementMax, which updates zetaN
+// incrementally instead of resumming from scratch.
+type incrementingZipfGenerator struct {
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	items uint64
+	theta float64
+	zetaN float64
+	zeta2 float64
+	alpha float64
+	eta   float64
+
+	// scrambleMode picks how values are spread across [0, items) instead of
+	// clustering around 0: scrambleModeFNV hashes with FNV-1a (as in YCSB's
+	// ScrambledZipfianGenerator), scrambleModePermutation reuses
+	// PermutationGenerator, and "" leaves values unscrambled.
+	scrambleMode string
+	perm         *PermutationGenerator
+	seed         int64
+}
+
+const (
+	scrambleModeFNV         = "fnv"
+	scrambleModePermutation = "permutation"
+)
+
+// newIncrementingZipfGenerator creates a generator over [0, items) with the
+// given zipfian theta (the skew exponent, in (0, 1)) and scrambleMode
+// ("", scrambleModeFNV, or scrambleModePermutation).
+func newIncrementingZipfGenerator(seed int64, items uint64, theta float64, scrambleMode string) *incrementingZipfGenerator {
+	g := &incrementingZipfGenerator{
+		rnd:          rand.New(rand.NewSource(seed)),
+		items:        items,
+		theta:        theta,
+		alpha:        1 / (1 - theta),
+		scrambleMode: scrambleMode,
+		seed:         seed,
+	}
+	g.zeta2 = zeta(0, 2, theta, 0)
+	g.zetaN = zeta(0, items, theta, 0)
+	g.computeEta()
+	if scrambleMode == scrambleModePermutation {
+		g.perm = NewPermutationGenerator(int64(items), seed)
+	}
+	return g
+}
+
+// zeta computes sum_{i=previousN+1..n} 1/i^theta, adding to previousZetaN.
+// Passing previousN=0 and previousZetaN=0 computes the sum from scratch.
+func zeta(previousN, n uint64, theta, previousZetaN float64) float64 {
+	sum := previousZetaN
+	for i := previousN + 1; i <= n; i++ {
+		sum += 1 / math.Pow(float64(i), theta)
+	}
+	return sum
+}
+
+func (g *incrementingZipfGenerator) computeEta() {
+	g.eta = (1 - math.Pow(2/float64(g.items), 1-g.theta)) / (1 - g.zeta2/g.zetaN)
+}
+
+// IncrementMax grows the key range by n items, updating zetaN incrementally
+// rather than recomputing the full sum. It is safe to call concurrently
+// with Next and with other calls to IncrementMax.
+func (g *incrementingZipfGenerator) IncrementMax(n uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.zetaN = zeta(g.items, g.items+n, g.theta, g.zetaN)
+	g.items += n
+	g.computeEta()
+	if g.scrambleMode == scrambleModePermutation {
+		g.perm = NewPermutationGenerator(int64(g.items), g.seed)
+	}
+}
+
+// Next draws the next value in [0, items).
+func (g *incrementingZipfGenerator) Next() uint64 {
+	g.mu.Lock()
+	items, theta, alpha, zetaN, eta := g.items, g.theta, g.alpha, g.zetaN, g.eta
+	u := g.rnd.Float64()
+	perm := g.perm
+	g.mu.Unlock()
+
+	uz := u * zetaN
+
+	var val uint64
+	switch {
+	case uz < 1:
+		val = 0
+	case uz < 1+math.Pow(0.5, theta):
+		val = 1
+	default:
+		val = uint64(float64(items) * math.Pow(eta*u-eta+1, alpha))
+	}
+
+	switch g.scrambleMode {
+	case scrambleModeFNV:
+		return scrambleFNV(val, items)
+	case scrambleModePermutation:
+		return uint64(perm.Next(int64(val)))
+	default:
+		return val
+	}
+}
+
+// scrambleFNV hashes v with FNV-1a and reduces it modulo n, spreading
+// clustered low values across the full [0, n) range.
+func scrambleFNV(v, n uint64) uint64 {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	h := fnv.New64a()
+	h.Write(buf[:])
+	return h.Sum64() % n
+}
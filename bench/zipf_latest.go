@@ -0,0 +1,127 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ZipfLatest sets random bits according to a Zipf distribution biased
+// towards the most recently inserted ID, modeling recency-skewed workloads
+// like social feeds or log tails. It tracks a `latest` counter that advances
+// on every set, and returns `(latest - k) mod N` for `k` drawn from the
+// underlying Zipf distribution.
+type ZipfLatest struct {
+	HasClient
+	Name            string  `json:"name"`
+	BaseBitmapID    int64   `json:"base-bitmap-id"`
+	BaseProfileID   int64   `json:"base-profile-id"`
+	BitmapIDRange   int64   `json:"bitmap-id-range"`
+	ProfileIDRange  int64   `json:"profile-id-range"`
+	Iterations      int     `json:"iterations"`
+	Seed            int64   `json:"seed"`
+	Index           string  `json:"index"`
+	Frame           string  `json:"frame"`
+	BitmapExponent  float64 `json:"bitmap-exponent"`
+	BitmapRatio     float64 `json:"bitmap-ratio"`
+	ProfileExponent float64 `json:"profile-exponent"`
+	ProfileRatio    float64 `json:"profile-ratio"`
+	Operation       string  `json:"operation"`
+	// HotspotFraction offsets where the peak of the distribution sits
+	// within the keyspace, as a fraction of the ID range behind the insert
+	// frontier: 0.0 peaks at the newest ID, 0.5 at the middle of the range.
+	HotspotFraction float64 `json:"hotspot-fraction"`
+	bitmapDist      Distribution
+	profileDist     Distribution
+	bitmapLatest    int64
+	profileLatest   int64
+}
+
+// Init sets up the benchmark based on the agent number and initializes the
+// client.
+func (b *ZipfLatest) Init(hosts []string, agentNum int) error {
+	b.Name = "zipf-latest"
+	b.Seed = b.Seed + int64(agentNum)
+
+	// k is drawn from a bare, unscrambled Zipf distribution so that small
+	// (high-probability) values cluster (latest - k) mod N near the insert
+	// frontier; zipf-mandelbrot's PermutationGenerator scrambling would
+	// scatter them across the whole keyspace instead.
+	var err error
+	b.bitmapDist, err = NewFromConfig(b.Seed, uint64(b.BitmapIDRange), DistributionConfig{
+		Name:   "zipf",
+		Params: map[string]float64{"exponent": b.BitmapExponent, "ratio": b.BitmapRatio},
+	})
+	if err != nil {
+		return fmt.Errorf("building bitmap distribution: %v", err)
+	}
+	b.profileDist, err = NewFromConfig(b.Seed+1, uint64(b.ProfileIDRange), DistributionConfig{
+		Name:   "zipf",
+		Params: map[string]float64{"exponent": b.ProfileExponent, "ratio": b.ProfileRatio},
+	})
+	if err != nil {
+		return fmt.Errorf("building profile distribution: %v", err)
+	}
+
+	b.bitmapLatest = -int64(float64(b.BitmapIDRange) * b.HotspotFraction)
+	b.profileLatest = -int64(float64(b.ProfileIDRange) * b.HotspotFraction)
+
+	if b.Operation != "set" && b.Operation != "clear" {
+		return fmt.Errorf("Unsupported operation: \"%s\" (must be \"set\" or \"clear\")", b.Operation)
+	}
+	err = initIndex(hosts[0], b.Index, b.Frame)
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	return b.HasClient.Init(hosts, agentNum)
+}
+
+// mod returns a non-negative a mod n, for n > 0.
+func mod(a, n int64) int64 {
+	m := a % n
+	if m < 0 {
+		m += n
+	}
+	return m
+}
+
+// Run runs the ZipfLatest benchmark
+func (b *ZipfLatest) Run(ctx context.Context) map[string]interface{} {
+	results := make(map[string]interface{})
+	if b.client == nil {
+		results["error"] = fmt.Errorf("No client set for ZipfLatest")
+		return results
+	}
+	operation := "SetBit"
+	if b.Operation == "clear" {
+		operation = "ClearBit"
+	}
+	s := NewStats()
+	var start time.Time
+	for n := 0; n < b.Iterations; n++ {
+		// draw an offset behind the insert frontier from the Zipf
+		// distribution, and bias the result towards the newest IDs
+		bitmapK := int64(b.bitmapDist.Next())
+		profK := int64(b.profileDist.Next())
+		bitmapID := mod(atomic.LoadInt64(&b.bitmapLatest)-bitmapK, b.BitmapIDRange)
+		profID := mod(atomic.LoadInt64(&b.profileLatest)-profK, b.ProfileIDRange)
+
+		query := fmt.Sprintf("%s(frame='%s', rowID=%d, columnID=%d)", operation, b.Frame, b.BaseBitmapID+bitmapID, b.BaseProfileID+profID)
+		start = time.Now()
+		_, err := b.client.ExecuteQuery(ctx, b.Index, query, true)
+		if err != nil {
+			results["error"] = err.Error()
+			return results
+		}
+		s.Add(time.Now().Sub(start))
+
+		if b.Operation == "set" {
+			atomic.AddInt64(&b.bitmapLatest, 1)
+			atomic.AddInt64(&b.profileLatest, 1)
+		}
+	}
+	AddToResults(s, results)
+	return results
+}